@@ -2,23 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
 	"dagger/http-nu/internal/dagger"
 )
 
 type HttpNu struct{}
 
-func (m *HttpNu) withCaches(container *dagger.Container, targetSuffix string) *dagger.Container {
-	// Separate caches per target
-	registryCache := dag.CacheVolume("dagger-cargo-registry-" + targetSuffix)
-	gitCache := dag.CacheVolume("dagger-cargo-git-" + targetSuffix)
-	targetCache := dag.CacheVolume("dagger-cargo-target-" + targetSuffix)
-
-	return container.
-		WithMountedCache("/root/.cargo/registry", registryCache).
-		WithMountedCache("/root/.cargo/git", gitCache).
-		WithMountedCache("/app/target", targetCache)
-}
-
 func (m *HttpNu) Upload(
 	ctx context.Context,
 	// +ignore=["**", "!Cargo.toml", "!Cargo.lock", "!src/**", "!xs.nu", "!scripts/**"]
@@ -26,89 +20,423 @@ func (m *HttpNu) Upload(
 	return src
 }
 
-func (m *HttpNu) DarwinEnv(
-	ctx context.Context,
-	src *dagger.Directory) *dagger.Container {
-	return m.withCaches(
-		dag.Container().
-			From("joseluisq/rust-linux-darwin-builder:latest").
-			WithMountedDirectory("/app", src).
-			WithWorkdir("/app"),
-		"darwin-arm64",
-	)
+// zigTargetInfo describes the archive suffix and binary name cargo-zigbuild
+// produces for a given Rust target triple.
+type zigTargetInfo struct {
+	suffix  string
+	binName string
+}
+
+// aarch64-pc-windows-msvc is deliberately absent: cargo-zigbuild has no
+// Zig-provided MSVC CRT, so it cannot link MSVC targets. windows/arm64 isn't
+// offered until that gap is covered by a gnullvm target or a real MSVC toolchain.
+var zigTargets = map[string]zigTargetInfo{
+	"aarch64-apple-darwin":       {"darwin-arm64", "http-nu"},
+	"x86_64-apple-darwin":        {"darwin-amd64", "http-nu"},
+	"aarch64-unknown-linux-musl": {"linux-arm64", "http-nu"},
+	"x86_64-unknown-linux-musl":  {"linux-amd64", "http-nu"},
+	"x86_64-pc-windows-gnu":      {"windows-amd64", "http-nu.exe"},
+}
+
+// macOSSDKURL points at the macOS SDK tarball cargo-zigbuild needs on its
+// SDKROOT to link aarch64/x86_64-apple-darwin targets - zig cc alone only
+// gets you the linker, not the Apple frameworks and libSystem stubs.
+const macOSSDKURL = "https://github.com/joseluisq/macosx-sdks/releases/download/12.3/MacOSX12.3.sdk.tar.xz"
+
+// ZigEnv installs Zig + rustup targets once into a shared base image and
+// dispatches cross-compilation via cargo-zigbuild, replacing the old mix of
+// per-target base images. One cargo registry/git cache is shared across all
+// targets, while each target keeps its own target/ cache.
+func (m *HttpNu) ZigEnv(ctx context.Context, src *dagger.Directory, target string) *dagger.Container {
+	registryCache := dag.CacheVolume("dagger-cargo-registry")
+	gitCache := dag.CacheVolume("dagger-cargo-git")
+	targetCache := dag.CacheVolume("dagger-cargo-target-" + target)
+
+	// Sorted so the rustup layer is identical, and cached, for every target.
+	targets := make([]string, 0, len(zigTargets))
+	for t := range zigTargets {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+
+	env := dag.Container().
+		From("rust:latest").
+		WithExec([]string{"apt-get", "update"}).
+		WithExec([]string{"apt-get", "install", "-y", "python3-pip", "curl", "xz-utils"}).
+		WithExec([]string{"pip", "install", "--break-system-packages", "ziglang"}).
+		WithExec([]string{"cargo", "install", "cargo-zigbuild"}).
+		WithExec(append([]string{"rustup", "target", "add"}, targets...))
+
+	if _, ok := zigTargets[target]; !ok {
+		env = env.WithExec([]string{"rustup", "target", "add", target})
+	}
+
+	if strings.Contains(target, "apple-darwin") {
+		env = env.
+			WithExec([]string{"sh", "-c", "mkdir -p /opt/macosx-sdk && curl -fsSL " + macOSSDKURL + " | tar -xJ -C /opt/macosx-sdk --strip-components=1"}).
+			WithEnvVariable("SDKROOT", "/opt/macosx-sdk")
+	}
+
+	return env.
+		WithMountedCache("/root/.cargo/registry", registryCache).
+		WithMountedCache("/root/.cargo/git", gitCache).
+		WithMountedCache("/app/target", targetCache).
+		WithMountedDirectory("/app", src).
+		WithWorkdir("/app")
+}
+
+// BuildTarget cross-compiles src for the given Rust target triple via
+// cargo-zigbuild and returns a release tarball. Adding a new target only
+// requires an entry in zigTargets, not a new *Env function.
+func (m *HttpNu) BuildTarget(ctx context.Context, src *dagger.Directory, target string) *dagger.File {
+	info, ok := zigTargets[target]
+	if !ok {
+		info = zigTargetInfo{suffix: target, binName: "http-nu"}
+	}
+
+	archivePath := fmt.Sprintf("/tmp/http-nu-%s.tar.gz", info.suffix)
+
+	return m.ZigEnv(ctx, src, target).
+		WithExec([]string{"cargo", "zigbuild", "--release", "--target", target}).
+		WithExec([]string{"tar", "-czf", archivePath, "-C", "/app/target/" + target + "/release", info.binName}).
+		File(archivePath)
 }
 
+// DarwinEnv is a thin wrapper over ZigEnv kept for backward compatibility.
+func (m *HttpNu) DarwinEnv(ctx context.Context, src *dagger.Directory) *dagger.Container {
+	return m.ZigEnv(ctx, src, "aarch64-apple-darwin")
+}
+
+// DarwinBuild is a thin wrapper over BuildTarget kept for backward compatibility.
 func (m *HttpNu) DarwinBuild(ctx context.Context, src *dagger.Directory) *dagger.File {
-	return m.DarwinEnv(ctx, src).
-		WithExec([]string{"./scripts/cross-build-darwin.sh", "--release"}).
-		WithExec([]string{"tar", "-czf", "/tmp/http-nu-darwin-arm64.tar.gz", "-C", "/app/target/aarch64-apple-darwin/release", "http-nu"}).
-		File("/tmp/http-nu-darwin-arm64.tar.gz")
+	return m.BuildTarget(ctx, src, "aarch64-apple-darwin")
 }
 
-func (m *HttpNu) WindowsEnv(
-	ctx context.Context,
-	src *dagger.Directory) *dagger.Container {
-	return m.withCaches(
-		dag.Container().
-			From("joseluisq/rust-linux-darwin-builder:latest").
-			WithExec([]string{"apt", "update"}).
-			WithExec([]string{"apt", "install", "-y", "nasm", "gcc-mingw-w64-i686", "mingw-w64", "mingw-w64-tools"}).
-			WithExec([]string{"rustup", "target", "add", "x86_64-pc-windows-gnu"}).
-			WithEnvVariable("CARGO_BUILD_TARGET", "x86_64-pc-windows-gnu").
-			WithEnvVariable("CC_x86_64_pc_windows_gnu", "x86_64-w64-mingw32-gcc").
-			WithEnvVariable("CXX_x86_64_pc_windows_gnu", "x86_64-w64-mingw32-g++").
-			WithEnvVariable("AR_x86_64_pc_windows_gnu", "x86_64-w64-mingw32-gcc-ar").
-			WithEnvVariable("DLLTOOL_x86_64_pc_windows_gnu", "x86_64-w64-mingw32-dlltool").
-			WithEnvVariable("CFLAGS_x86_64_pc_windows_gnu", "-m64").
-			WithEnvVariable("ASM_NASM_x86_64_pc_windows_gnu", "/usr/bin/nasm").
-			WithEnvVariable("AWS_LC_SYS_PREBUILT_NASM", "0").
-			WithMountedDirectory("/app", src).
-			WithWorkdir("/app"),
-		"windows-amd64",
-	)
+// WindowsEnv is a thin wrapper over ZigEnv kept for backward compatibility.
+func (m *HttpNu) WindowsEnv(ctx context.Context, src *dagger.Directory) *dagger.Container {
+	return m.ZigEnv(ctx, src, "x86_64-pc-windows-gnu")
 }
 
+// WindowsBuild is a thin wrapper over BuildTarget kept for backward compatibility.
 func (m *HttpNu) WindowsBuild(ctx context.Context, src *dagger.Directory) *dagger.File {
-	return m.WindowsEnv(ctx, src).
-		WithExec([]string{"cargo", "build", "--release"}).
-		WithExec([]string{"tar", "-czf", "/tmp/http-nu-windows-amd64.tar.gz", "-C", "/app/target/x86_64-pc-windows-gnu/release", "http-nu.exe"}).
-		File("/tmp/http-nu-windows-amd64.tar.gz")
+	return m.BuildTarget(ctx, src, "x86_64-pc-windows-gnu")
 }
 
-func (m *HttpNu) LinuxArm64Env(
-	ctx context.Context,
-	src *dagger.Directory) *dagger.Container {
-	return m.withCaches(
-		dag.Container().
-			From("messense/rust-musl-cross:aarch64-musl").
-			WithMountedDirectory("/app", src).
-			WithWorkdir("/app"),
-		"linux-arm64",
-	)
+// LinuxArm64Env is a thin wrapper over ZigEnv kept for backward compatibility.
+func (m *HttpNu) LinuxArm64Env(ctx context.Context, src *dagger.Directory) *dagger.Container {
+	return m.ZigEnv(ctx, src, "aarch64-unknown-linux-musl")
 }
 
+// LinuxArm64Build is a thin wrapper over BuildTarget kept for backward compatibility.
 func (m *HttpNu) LinuxArm64Build(ctx context.Context, src *dagger.Directory) *dagger.File {
-	return m.LinuxArm64Env(ctx, src).
-		WithExec([]string{"cargo", "build", "--release", "--target", "aarch64-unknown-linux-musl"}).
-		WithExec([]string{"tar", "-czf", "/tmp/http-nu-linux-arm64.tar.gz", "-C", "/app/target/aarch64-unknown-linux-musl/release", "http-nu"}).
-		File("/tmp/http-nu-linux-arm64.tar.gz")
+	return m.BuildTarget(ctx, src, "aarch64-unknown-linux-musl")
 }
 
+// LinuxAmd64Env builds on ZigEnv so Test/Clippy/Fmt/Audit/Check/GenerateSBOM
+// share the same cargo registry/git/target caches as BuildTarget, plus the
+// cargo subcommands and rustup components those checks need.
 func (m *HttpNu) LinuxAmd64Env(
 	ctx context.Context,
 	src *dagger.Directory) *dagger.Container {
-	return m.withCaches(
-		dag.Container().
-			From("messense/rust-musl-cross:x86_64-musl").
-			WithMountedDirectory("/app", src).
-			WithWorkdir("/app"),
-		"linux-amd64",
-	)
+	return m.ZigEnv(ctx, src, "x86_64-unknown-linux-musl").
+		WithExec([]string{"rustup", "component", "add", "clippy", "rustfmt"}).
+		WithExec([]string{"cargo", "install", "cargo-nextest", "cargo-audit", "cargo-cyclonedx"})
 }
 
+// LinuxAmd64Build is a thin wrapper over BuildTarget kept for backward compatibility.
 func (m *HttpNu) LinuxAmd64Build(ctx context.Context, src *dagger.Directory) *dagger.File {
+	return m.BuildTarget(ctx, src, "x86_64-unknown-linux-musl")
+}
+
+// PublishedRefs carries the digests produced by a Publish call so downstream
+// pipelines can reference the pushed manifest list or a specific platform image.
+type PublishedRefs struct {
+	ManifestDigest  string
+	PlatformDigests map[string]string
+}
+
+// platformTargets maps an OCI platform to the Rust target triple ZigEnv
+// should cross-compile for.
+var platformTargets = map[dagger.Platform]string{
+	"linux/amd64":   "x86_64-unknown-linux-musl",
+	"linux/arm64":   "aarch64-unknown-linux-musl",
+	"windows/amd64": "x86_64-pc-windows-gnu",
+	"darwin/arm64":  "aarch64-apple-darwin",
+}
+
+// Image assembles a minimal runtime image for the given platform by layering
+// the matching release binary over a scratch base, similar to nixpkgs'
+// streamLayeredImage: the binary lives in its own layer so pulls dedupe well
+// across tag updates.
+func (m *HttpNu) Image(ctx context.Context, src *dagger.Directory, platform dagger.Platform) *dagger.Container {
+	target, ok := platformTargets[platform]
+	if !ok {
+		return nil
+	}
+	info := zigTargets[target]
+
+	builtPath := fmt.Sprintf("/app/target/%s/release/%s", target, info.binName)
+	bin := m.ZigEnv(ctx, src, target).
+		WithExec([]string{"cargo", "zigbuild", "--release", "--target", target}).
+		WithExec([]string{"cp", builtPath, "/tmp/" + info.binName}).
+		File("/tmp/" + info.binName)
+
+	return dag.Container(dagger.ContainerOpts{Platform: platform}).
+		WithFile("/"+info.binName, bin).
+		WithEntrypoint([]string{"/" + info.binName})
+}
+
+// Publish assembles the LinuxAmd64Build, LinuxArm64Build, WindowsBuild, and
+// DarwinBuild outputs into per-platform images and pushes them as a single
+// OCI manifest list covering linux/amd64, linux/arm64, windows/amd64, and
+// darwin/arm64.
+func (m *HttpNu) Publish(
+	ctx context.Context,
+	src *dagger.Directory,
+	registry string,
+	tags []string,
+	username *dagger.Secret,
+	password *dagger.Secret,
+) (*PublishedRefs, error) {
+	user, err := username.Plaintext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	platforms := []dagger.Platform{"linux/amd64", "linux/arm64", "windows/amd64", "darwin/arm64"}
+	variants := make([]*dagger.Container, len(platforms))
+	for i, platform := range platforms {
+		variants[i] = m.Image(ctx, src, platform).
+			WithRegistryAuth(registry, user, password)
+	}
+
+	refs := &PublishedRefs{PlatformDigests: map[string]string{}}
+	for _, tag := range tags {
+		digest, err := variants[0].Publish(ctx, fmt.Sprintf("%s:%s", registry, tag), dagger.ContainerPublishOpts{
+			PlatformVariants: variants[1:],
+		})
+		if err != nil {
+			return nil, err
+		}
+		refs.ManifestDigest = digest
+	}
+
+	for i, platform := range platforms {
+		digest, err := variants[i].Digest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		refs.PlatformDigests[string(platform)] = digest
+	}
+
+	return refs, nil
+}
+
+func (m *HttpNu) Test(ctx context.Context, src *dagger.Directory) (string, error) {
+	return m.LinuxAmd64Env(ctx, src).
+		WithExec([]string{"cargo", "nextest", "run"}).
+		Stdout(ctx)
+}
+
+func (m *HttpNu) Clippy(ctx context.Context, src *dagger.Directory) (string, error) {
+	return m.LinuxAmd64Env(ctx, src).
+		WithExec([]string{"cargo", "clippy", "--all-targets", "--", "-D", "warnings"}).
+		Stdout(ctx)
+}
+
+func (m *HttpNu) Fmt(ctx context.Context, src *dagger.Directory) (string, error) {
 	return m.LinuxAmd64Env(ctx, src).
-		WithExec([]string{"cargo", "build", "--release", "--target", "x86_64-unknown-linux-musl"}).
-		WithExec([]string{"tar", "-czf", "/tmp/http-nu-linux-amd64.tar.gz", "-C", "/app/target/x86_64-unknown-linux-musl/release", "http-nu"}).
-		File("/tmp/http-nu-linux-amd64.tar.gz")
+		WithExec([]string{"cargo", "fmt", "--check"}).
+		Stdout(ctx)
+}
+
+func (m *HttpNu) Audit(ctx context.Context, src *dagger.Directory) (string, error) {
+	return m.LinuxAmd64Env(ctx, src).
+		WithExec([]string{"cargo", "audit"}).
+		Stdout(ctx)
+}
+
+// Check fans Test, Clippy, Fmt, and Audit out in parallel, sharing the same
+// ZigEnv volumes as the build targets so cargo artifacts are reused, and
+// returns a JUnit XML report that can be uploaded by a CI job. Producing
+// /app/target/nextest/ci/junit.xml requires a `[profile.ci.junit]` entry in
+// the repo's .config/nextest.toml; nextest won't write a report without one.
+func (m *HttpNu) Check(ctx context.Context, src *dagger.Directory) (*dagger.File, error) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	testRun := m.LinuxAmd64Env(ctx, src).
+		WithExec([]string{"cargo", "nextest", "run", "--profile", "ci"}).
+		WithExec([]string{"cp", "/app/target/nextest/ci/junit.xml", "/tmp/junit.xml"})
+
+	g.Go(func() error {
+		_, err := testRun.Stdout(ctx)
+		return err
+	})
+	g.Go(func() error {
+		_, err := m.Clippy(ctx, src)
+		return err
+	})
+	g.Go(func() error {
+		_, err := m.Fmt(ctx, src)
+		return err
+	})
+	g.Go(func() error {
+		_, err := m.Audit(ctx, src)
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return testRun.File("/tmp/junit.xml"), nil
+}
+
+// GenerateSBOM runs cargo cyclonedx against the sources to emit a CycloneDX
+// JSON SBOM for the release. cargo-cyclonedx names the file after the crate
+// (http-nu.cdx.json), written alongside Cargo.toml.
+func (m *HttpNu) GenerateSBOM(ctx context.Context, src *dagger.Directory) *dagger.File {
+	return m.LinuxAmd64Env(ctx, src).
+		WithExec([]string{"cargo", "cyclonedx", "--format", "json"}).
+		WithExec([]string{"cp", "/app/http-nu.cdx.json", "/tmp/http-nu.cdx.json"}).
+		File("/tmp/http-nu.cdx.json")
+}
+
+// Release invokes all four *Build functions in parallel, renames each
+// tarball to include version, computes SHA-256 and BLAKE3 checksums, and
+// optionally cosign-signs each artifact and the checksum files when
+// signingKey is provided. Everything is returned in one Directory ready to
+// hand to a `gh release upload`-style step.
+func (m *HttpNu) Release(
+	ctx context.Context,
+	src *dagger.Directory,
+	version string,
+	signingKey *dagger.Secret,
+) (*dagger.Directory, error) {
+	builds := []struct {
+		suffix string
+		build  func(context.Context, *dagger.Directory) *dagger.File
+	}{
+		{"linux-amd64", m.LinuxAmd64Build},
+		{"linux-arm64", m.LinuxArm64Build},
+		{"windows-amd64", m.WindowsBuild},
+		{"darwin-arm64", m.DarwinBuild},
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	names := make([]string, len(builds))
+	files := make([]*dagger.File, len(builds))
+
+	for i, b := range builds {
+		i, b := i, b
+		names[i] = fmt.Sprintf("http-nu-%s-%s.tar.gz", version, b.suffix)
+		g.Go(func() error {
+			files[i] = b.build(ctx, src)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sbomName := fmt.Sprintf("http-nu-%s.cdx.json", version)
+
+	dir := dag.Directory()
+	for i, name := range names {
+		dir = dir.WithFile(name, files[i])
+	}
+	dir = dir.WithFile(sbomName, m.GenerateSBOM(ctx, src))
+
+	checksums := dag.Container().
+		From("alpine:latest").
+		WithExec([]string{"apk", "add", "--no-cache", "b3sum"}).
+		WithMountedDirectory("/release", dir).
+		WithWorkdir("/release").
+		WithExec([]string{"sh", "-c", "sha256sum * > /tmp/SHA256SUMS"}).
+		WithExec([]string{"sh", "-c", "b3sum * > /tmp/B3SUMS"})
+
+	dir = dir.
+		WithFile("SHA256SUMS", checksums.File("/tmp/SHA256SUMS")).
+		WithFile("B3SUMS", checksums.File("/tmp/B3SUMS"))
+
+	if signingKey == nil {
+		return dir, nil
+	}
+
+	toSign := append(append([]string{}, names...), sbomName, "SHA256SUMS", "B3SUMS")
+	signer := dag.Container().
+		From("gcr.io/projectsigstore/cosign:latest").
+		WithMountedSecret("/run/secrets/cosign.key", signingKey).
+		WithMountedDirectory("/release", dir).
+		WithWorkdir("/release")
+
+	for _, name := range toSign {
+		sigPath := "/tmp/" + name + ".sig"
+		signer = signer.WithExec([]string{
+			"cosign", "sign-blob",
+			"--key", "/run/secrets/cosign.key",
+			"--yes",
+			"--output-signature", sigPath,
+			name,
+		})
+		dir = dir.WithFile(name+".sig", signer.File(sigPath))
+	}
+
+	return dir, nil
+}
+
+// Dev returns a fully-prepared build container with cargo, rustup, the
+// correct cross toolchain, and src mounted at /app, so a developer can run
+// `dagger call dev --src=. terminal` to drop into an identical shell to CI.
+// An empty target defaults to the host's platform.
+func (m *HttpNu) Dev(ctx context.Context, src *dagger.Directory, target string) *dagger.Container {
+	if target == "" {
+		registryCache := dag.CacheVolume("dagger-cargo-registry")
+		gitCache := dag.CacheVolume("dagger-cargo-git")
+		targetCache := dag.CacheVolume("dagger-cargo-target-host")
+
+		return dag.Container().
+			From("rust:latest").
+			WithMountedCache("/root/.cargo/registry", registryCache).
+			WithMountedCache("/root/.cargo/git", gitCache).
+			WithMountedCache("/app/target", targetCache).
+			WithMountedDirectory("/app", src).
+			WithWorkdir("/app")
+	}
+	return m.ZigEnv(ctx, src, target)
+}
+
+// Shell is the Nushell-preinstalled variant of Dev, since http-nu embeds nushell.
+func (m *HttpNu) Shell(ctx context.Context, src *dagger.Directory) *dagger.Container {
+	return m.Dev(ctx, src, "").
+		WithExec([]string{"cargo", "install", "nu", "--locked"}).
+		WithDefaultTerminalCmd([]string{"nu"})
+}
+
+// Repro replays the exact command sequence of a named CI job (darwin-build,
+// windows-build, linux-amd64-build, linux-arm64-build, test, clippy) with
+// `set -x` so build divergence between local and CI is easy to spot.
+func (m *HttpNu) Repro(ctx context.Context, src *dagger.Directory, failedJob string) (*dagger.Container, error) {
+	switch failedJob {
+	case "darwin-build":
+		return m.DarwinEnv(ctx, src).
+			WithExec([]string{"sh", "-xc", "cargo zigbuild --release --target aarch64-apple-darwin && tar -czf /tmp/http-nu-darwin-arm64.tar.gz -C /app/target/aarch64-apple-darwin/release http-nu"}), nil
+	case "windows-build":
+		return m.WindowsEnv(ctx, src).
+			WithExec([]string{"sh", "-xc", "cargo zigbuild --release --target x86_64-pc-windows-gnu && tar -czf /tmp/http-nu-windows-amd64.tar.gz -C /app/target/x86_64-pc-windows-gnu/release http-nu.exe"}), nil
+	case "linux-amd64-build":
+		return m.ZigEnv(ctx, src, "x86_64-unknown-linux-musl").
+			WithExec([]string{"sh", "-xc", "cargo zigbuild --release --target x86_64-unknown-linux-musl && tar -czf /tmp/http-nu-linux-amd64.tar.gz -C /app/target/x86_64-unknown-linux-musl/release http-nu"}), nil
+	case "linux-arm64-build":
+		return m.ZigEnv(ctx, src, "aarch64-unknown-linux-musl").
+			WithExec([]string{"sh", "-xc", "cargo zigbuild --release --target aarch64-unknown-linux-musl && tar -czf /tmp/http-nu-linux-arm64.tar.gz -C /app/target/aarch64-unknown-linux-musl/release http-nu"}), nil
+	case "test":
+		return m.LinuxAmd64Env(ctx, src).
+			WithExec([]string{"sh", "-xc", "cargo nextest run"}), nil
+	case "clippy":
+		return m.LinuxAmd64Env(ctx, src).
+			WithExec([]string{"sh", "-xc", "cargo clippy --all-targets -- -D warnings"}), nil
+	default:
+		return nil, fmt.Errorf("unknown CI job %q", failedJob)
+	}
 }